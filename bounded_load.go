@@ -0,0 +1,128 @@
+package consistent_hash
+
+import "math"
+
+/*
+	有界负载一致性哈希 (Consistent Hashing with Bounded Loads)
+	参考 Google 的论文思路：在普通一致性哈希的基础上，限制每个物理节点能够承载的
+	key数量上限，避免热点key或环分布不均导致个别节点负载过高。
+
+	核心公式：
+	- avg = ceil((totalLoad+1) / numMembers)         // 平均负载（算上本次新请求）
+	- capPerNode = ceil(avg * LoadFactor)            // 每个节点允许的负载上限
+	- 从key的hash位置开始顺时针查找，跳过负载已达上限的节点，
+	  返回第一个未超过capPerNode的节点；如果所有节点都已达到上限，
+	  则退化为普通的Get，返回自然归属的节点。
+*/
+
+// Lease 表示一次 GetBounded 分配，调用方在请求/会话结束后应调用 Release
+// 以归还负载计数，否则该节点的负载会被持续占用，影响后续的负载均衡判断。
+type Lease struct {
+	c      *ConsistentHash
+	member string
+	done   bool
+}
+
+// Release 归还本次租约占用的负载计数，可安全地重复调用。
+func (l *Lease) Release() {
+	if l == nil || l.done {
+		return
+	}
+	l.done = true
+	l.c.Done(l.member)
+}
+
+// capPerNode 计算当前配置下每个节点允许的负载上限
+func (c *ConsistentHash) capPerNode() int64 {
+	loadFactor := c.config.LoadFactor
+	if loadFactor <= 0 {
+		loadFactor = DefaultLoadFactor
+	}
+	numMembers := int64(len(c.members))
+	avg := math.Ceil(float64(c.totalLoad+1) / float64(numMembers))
+	return int64(math.Ceil(avg * loadFactor))
+}
+
+// DefaultLoadFactor 为未显式配置 LoadFactor 时使用的默认放大系数
+const DefaultLoadFactor = 1.25
+
+// GetBounded 按照有界负载策略返回key应当分配到的节点：从key的自然归属位置
+// 顺时针查找第一个负载未达上限的节点；若所有节点都已达到负载上限，
+// 则退化为自然归属节点（即 Get 的结果）。
+//
+// 调用方应在请求处理完成后调用 Done 归还负载，或改用 GetBoundedLease
+// 获得一个可 Release 的租约句柄。
+func (c *ConsistentHash) GetBounded(key string) string {
+	c.Lock()
+	defer c.Unlock()
+
+	if len(c.ring) == 0 {
+		return ""
+	}
+
+	idx, natural := c.findNode(c.hashKey(key))
+	if len(c.members) == 0 {
+		return natural
+	}
+
+	capLimit := c.capPerNode()
+	visited := make(map[string]struct{}, len(c.members))
+	for i := 0; i < len(c.ring); i++ {
+		member := c.hashMap[c.ring[idx]]
+		if _, ok := visited[member]; !ok {
+			visited[member] = struct{}{}
+			if c.loads[member] < capLimit {
+				c.inc(member)
+				return member
+			}
+			if len(visited) == len(c.members) {
+				break
+			}
+		}
+		idx = (idx + 1) % len(c.ring)
+	}
+
+	// 所有节点都已达到负载上限，退化为自然归属节点
+	c.inc(natural)
+	return natural
+}
+
+// GetBoundedLease 与 GetBounded 行为一致，但返回一个 Lease 句柄，
+// 调用方可以通过 lease.Release() 归还负载计数，而不必记住分配到的节点名。
+func (c *ConsistentHash) GetBoundedLease(key string) *Lease {
+	member := c.GetBounded(key)
+	if member == "" {
+		return nil
+	}
+	return &Lease{c: c, member: member}
+}
+
+// Inc 为member增加一次负载计数，用于手动记录一次分配（例如恢复已有会话）。
+func (c *ConsistentHash) Inc(member string) {
+	c.Lock()
+	defer c.Unlock()
+	c.inc(member)
+}
+
+// inc 在已持有锁的前提下增加member的负载计数
+func (c *ConsistentHash) inc(member string) {
+	if _, ok := c.members[member]; !ok {
+		return
+	}
+	c.loads[member]++
+	c.totalLoad++
+}
+
+// Done 归还一次之前由 GetBounded/Inc 记录在member上的负载计数。
+func (c *ConsistentHash) Done(member string) {
+	c.Lock()
+	defer c.Unlock()
+
+	if _, ok := c.members[member]; !ok {
+		return
+	}
+	if c.loads[member] > 0 {
+		c.loads[member]--
+		c.totalLoad--
+	}
+}
@@ -0,0 +1,55 @@
+package consistent_hash
+
+import (
+	"fmt"
+	"testing"
+)
+
+// 验证当某个节点的负载达到上限后，GetBounded会将key分流到下一个未满的节点
+func Test_GetBounded(t *testing.T) {
+	ch := New(&Config{
+		Replicas:   1,
+		HashFunc:   NewCRC32(),
+		LoadFactor: 1.0,
+	})
+	ch.Add("node1", "node2", "node3")
+
+	seen := make(map[string]int)
+	for i := 0; i < 30; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		member := ch.GetBounded(key)
+		if member == "" {
+			t.Fatalf("expected a member for key %s", key)
+		}
+		seen[member]++
+	}
+
+	capLimit := ch.capPerNode()
+	for member, count := range seen {
+		if int64(count) > capLimit+1 {
+			t.Errorf("member %s exceeded bounded load: got %d, cap %d", member, count, capLimit)
+		}
+	}
+}
+
+// 验证GetBoundedLease会记录一次负载，lease.Release()（内部调用Done）后负载计数归零
+func Test_GetBounded_Release(t *testing.T) {
+	ch := New(&Config{
+		Replicas:   1,
+		HashFunc:   NewCRC32(),
+		LoadFactor: 1.0,
+	})
+	ch.Add("node1")
+
+	lease := ch.GetBoundedLease("key-1")
+	if lease == nil {
+		t.Fatal("expected a lease")
+	}
+	if ch.loads["node1"] != 1 {
+		t.Fatalf("expected load 1, got %d", ch.loads["node1"])
+	}
+	lease.Release()
+	if ch.loads["node1"] != 0 {
+		t.Fatalf("expected load 0 after release, got %d", ch.loads["node1"])
+	}
+}
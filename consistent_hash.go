@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strconv"
 	"sync"
+	"time"
 )
 
 /*
@@ -34,6 +35,11 @@ func (c *CRC32) Hash(key []byte) uint32 {
 	return crc32.ChecksumIEEE(key)
 }
 
+// Name 返回该哈希函数在哈希函数注册表中的名字，用于Snapshot/Restore
+func (c *CRC32) Name() string {
+	return "crc32"
+}
+
 func NewCRC32() Hasher {
 	return &CRC32{}
 }
@@ -47,8 +53,12 @@ var DefaultHasher = NewCRC32()
 
 // Config 定义一致性哈希的配置选项
 type Config struct {
-	Replicas int    // 每个节点的基础虚拟节点数，最终节点的虚拟节点数量 = Replicas × 节点权重
-	HashFunc Hasher // 哈希函数
+	Replicas            int           // 每个节点的基础虚拟节点数，最终节点的虚拟节点数量 = Replicas × 节点权重
+	HashFunc            Hasher        // 哈希函数
+	LoadFactor          float64       // 有界负载的放大系数，如1.25；<=0 时使用DefaultLoadFactor，详见 GetBounded
+	HealthChecker       HealthChecker // 可选，设置后哈希环会周期性探测成员健康状态并自动MarkDown/MarkUp
+	HealthCheckInterval time.Duration // 健康检查周期，<=0时使用DefaultHealthCheckInterval
+	Strategy            Strategy      // 选择Ring还是Rendezvous作为底层实现，详见 NewHash
 }
 
 // DefaultConfig 返回默认配置
@@ -60,12 +70,18 @@ func DefaultConfig() *Config {
 }
 
 type ConsistentHash struct {
-	config  *Config
-	hash    Hasher
-	ring    []uint32            // 哈希环(记录的是hash值)
-	weights map[string]int      // 节点权重
-	hashMap map[uint32]string   // 记录hash环上的节点映射真实节点，方便后续查找
-	members map[string]struct{} // 记录已加入的真实节点
+	config    *Config
+	hash      Hasher
+	ring      []uint32            // 哈希环(记录的是hash值)
+	weights   map[string]int      // 节点权重
+	hashMap   map[uint32]string   // 记录hash环上的节点映射真实节点，方便后续查找
+	members   map[string]struct{} // 记录已加入的真实节点
+	loads     map[string]int64    // 每个节点当前承载的key数量，用于有界负载限制，详见 GetBounded
+	totalLoad int64               // 所有节点承载的key数量总和
+	down      map[string]struct{} // 被标记为不可用的节点，详见 MarkDown/GetLive
+
+	subscribers     []chan Event // Events 的订阅者列表
+	stopHealthCheck chan struct{}
 	sync.RWMutex
 }
 
@@ -74,14 +90,20 @@ func New(config *Config) *ConsistentHash {
 	if config == nil {
 		config = DefaultConfig()
 	}
-	return &ConsistentHash{
+	c := &ConsistentHash{
 		config:  config,
 		hash:    config.HashFunc,
 		ring:    make([]uint32, 0),
 		weights: make(map[string]int),
 		hashMap: make(map[uint32]string),
 		members: make(map[string]struct{}),
+		loads:   make(map[string]int64),
+		down:    make(map[string]struct{}),
+	}
+	if config.HealthChecker != nil {
+		c.startHealthCheck()
 	}
+	return c
 }
 
 func (c *ConsistentHash) SetHasher(hash Hasher) {
@@ -97,11 +119,14 @@ func (c *ConsistentHash) SetVirtualReplicas(replicas int) {
 
 func (c *ConsistentHash) hashKey(key string) uint32 {
 	// 为了减少内存分配过于频繁，指定固定大小的字节数组，减少内存分配带来的性能开销
+	// 注意：只能对buf[:len(key)]求哈希，否则尾部的零填充会被一起计算，
+	// 对包括CRC32在内的所有哈希函数都会影响结果（即本次改动也会改变
+	// CRC32下已有环上的节点分布，并非只影响新增的Murmur3/FNV1a/XXHash）。
 	if len(key) <= 64 {
 		var buf [64]byte
-		copy(buf[:], key)
-		// fmt.Println("hashKey", key, "=", c.hash.Hash(buf[:]))
-		return c.hash.Hash(buf[:])
+		n := copy(buf[:], key)
+		// fmt.Println("hashKey", key, "=", c.hash.Hash(buf[:n]))
+		return c.hash.Hash(buf[:n])
 	}
 	return c.hash.Hash([]byte(key))
 }
@@ -121,6 +146,7 @@ func (c *ConsistentHash) addNode(member string, weight int) error {
 
 	c.members[member] = struct{}{}
 	c.weights[member] = weight
+	c.loads[member] = 0
 
 	// Calculate virtual nodes based on weight
 	replicas := c.config.Replicas * weight
@@ -238,6 +264,9 @@ func (c *ConsistentHash) Remove(members ...string) {
 		replicas := c.config.Replicas * c.weights[member]
 		delete(c.members, member)
 		delete(c.weights, member)
+		c.totalLoad -= c.loads[member]
+		delete(c.loads, member)
+		delete(c.down, member)
 
 		for i := 0; i < replicas; i++ {
 			// 计算这个member对应的hash值，然后删除
@@ -0,0 +1,199 @@
+package consistent_hash
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"sync"
+)
+
+/*
+	除了默认的CRC32之外，再提供几种在短key（如节点ID、host名）场景下
+	雪崩效应/均匀性表现更好的哈希函数，并提供一个按名称注册/查找的
+	哈希函数注册表，方便Config从配置文件中按名称加载。
+*/
+
+// Murmur3 哈希函数，基于MurmurHash3 x86_32实现
+type Murmur3 struct {
+	Seed uint32
+}
+
+func NewMurmur3() Hasher {
+	return &Murmur3{}
+}
+
+func (m *Murmur3) Hash(key []byte) uint32 {
+	return murmur3Sum32(key, m.Seed)
+}
+
+// Name 返回该哈希函数在哈希函数注册表中的名字，用于Snapshot/Restore
+func (m *Murmur3) Name() string {
+	return "murmur3"
+}
+
+func murmur3Sum32(data []byte, seed uint32) uint32 {
+	const (
+		c1 = 0xcc9e2d51
+		c2 = 0x1b873593
+	)
+
+	h := seed
+	nblocks := len(data) / 4
+	for i := 0; i < nblocks; i++ {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+
+	var k1 uint32
+	tail := data[nblocks*4:]
+	switch len(tail) {
+	case 3:
+		k1 ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k1 ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k1 ^= uint32(tail[0])
+		k1 *= c1
+		k1 = bits.RotateLeft32(k1, 15)
+		k1 *= c2
+		h ^= k1
+	}
+
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
+
+// FNV1a 哈希函数，基于标准库hash/fnv的FNV-1a变体
+type FNV1a struct{}
+
+func NewFNV1a() Hasher {
+	return &FNV1a{}
+}
+
+func (f *FNV1a) Hash(key []byte) uint32 {
+	h := fnv.New32a()
+	h.Write(key) //nolint:errcheck // hash.Hash.Write从不返回错误
+	return h.Sum32()
+}
+
+// Name 返回该哈希函数在哈希函数注册表中的名字，用于Snapshot/Restore
+func (f *FNV1a) Name() string {
+	return "fnv1a"
+}
+
+// XXHash 哈希函数，基于xxHash32算法的纯Go实现
+type XXHash struct {
+	Seed uint32
+}
+
+func NewXXHash() Hasher {
+	return &XXHash{}
+}
+
+func (x *XXHash) Hash(key []byte) uint32 {
+	return xxhash32Sum(key, x.Seed)
+}
+
+// Name 返回该哈希函数在哈希函数注册表中的名字，用于Snapshot/Restore
+func (x *XXHash) Name() string {
+	return "xxhash"
+}
+
+const (
+	xxhPrime1 uint32 = 2654435761
+	xxhPrime2 uint32 = 2246822519
+	xxhPrime3 uint32 = 3266489917
+	xxhPrime4 uint32 = 668265263
+	xxhPrime5 uint32 = 374761393
+)
+
+func xxhRound(acc, input uint32) uint32 {
+	acc += input * xxhPrime2
+	acc = bits.RotateLeft32(acc, 13)
+	acc *= xxhPrime1
+	return acc
+}
+
+func xxhash32Sum(input []byte, seed uint32) uint32 {
+	n := len(input)
+	i := 0
+	var h uint32
+
+	if n >= 16 {
+		v1 := seed + xxhPrime1 + xxhPrime2
+		v2 := seed + xxhPrime2
+		v3 := seed
+		v4 := seed - xxhPrime1
+		for ; i+16 <= n; i += 16 {
+			v1 = xxhRound(v1, binary.LittleEndian.Uint32(input[i:]))
+			v2 = xxhRound(v2, binary.LittleEndian.Uint32(input[i+4:]))
+			v3 = xxhRound(v3, binary.LittleEndian.Uint32(input[i+8:]))
+			v4 = xxhRound(v4, binary.LittleEndian.Uint32(input[i+12:]))
+		}
+		h = bits.RotateLeft32(v1, 1) + bits.RotateLeft32(v2, 7) +
+			bits.RotateLeft32(v3, 12) + bits.RotateLeft32(v4, 18)
+	} else {
+		h = seed + xxhPrime5
+	}
+
+	h += uint32(n)
+
+	for ; i+4 <= n; i += 4 {
+		h += binary.LittleEndian.Uint32(input[i:]) * xxhPrime3
+		h = bits.RotateLeft32(h, 17) * xxhPrime4
+	}
+
+	for ; i < n; i++ {
+		h += uint32(input[i]) * xxhPrime5
+		h = bits.RotateLeft32(h, 11) * xxhPrime1
+	}
+
+	h ^= h >> 15
+	h *= xxhPrime2
+	h ^= h >> 13
+	h *= xxhPrime3
+	h ^= h >> 16
+	return h
+}
+
+// hasherRegistry 保存按名称注册的哈希函数工厂，用于从配置文件按名称加载
+var (
+	hasherRegistryMu sync.RWMutex
+	hasherRegistry   = map[string]func() Hasher{
+		"crc32":   func() Hasher { return NewCRC32() },
+		"murmur3": func() Hasher { return NewMurmur3() },
+		"fnv1a":   func() Hasher { return NewFNV1a() },
+		"xxhash":  func() Hasher { return NewXXHash() },
+	}
+)
+
+// RegisterHasher 注册一个哈希函数工厂，name重复注册时会覆盖之前的工厂
+func RegisterHasher(name string, factory func() Hasher) {
+	hasherRegistryMu.Lock()
+	defer hasherRegistryMu.Unlock()
+	hasherRegistry[name] = factory
+}
+
+// HasherByName 按名称查找并实例化一个哈希函数，未注册时返回error
+func HasherByName(name string) (Hasher, error) {
+	hasherRegistryMu.RLock()
+	factory, ok := hasherRegistry[name]
+	hasherRegistryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("consistent_hash: no hasher registered with name %q", name)
+	}
+	return factory(), nil
+}
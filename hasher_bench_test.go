@@ -0,0 +1,51 @@
+package consistent_hash
+
+import (
+	"fmt"
+	"testing"
+)
+
+// chiSquare对[0, buckets)范围内的哈希分布做卡方检验，值越接近buckets
+// 表示分布越均匀（自由度为buckets-1的卡方分布期望值约等于buckets-1）
+func chiSquare(hasher Hasher, sampleCount, buckets int) float64 {
+	counts := make([]int, buckets)
+	for i := 0; i < sampleCount; i++ {
+		key := fmt.Sprintf("bench-key-%d", i)
+		h := hasher.Hash([]byte(key))
+		counts[int(h)%buckets]++
+	}
+
+	expected := float64(sampleCount) / float64(buckets)
+	var chi2 float64
+	for _, c := range counts {
+		diff := float64(c) - expected
+		chi2 += diff * diff / expected
+	}
+	return chi2
+}
+
+// Benchmark_HasherDistribution 对比CRC32/Murmur3/FNV1a/XXHash四种哈希函数
+// 在100万个短key上的分布质量（卡方值越接近桶数，分布越均匀）
+func Benchmark_HasherDistribution(b *testing.B) {
+	const (
+		sampleCount = 1_000_000
+		buckets     = 1024
+	)
+
+	hashers := map[string]Hasher{
+		"CRC32":   NewCRC32(),
+		"Murmur3": NewMurmur3(),
+		"FNV1a":   NewFNV1a(),
+		"XXHash":  NewXXHash(),
+	}
+
+	for name, hasher := range hashers {
+		hasher := hasher
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				chi2 := chiSquare(hasher, sampleCount, buckets)
+				b.ReportMetric(chi2, "chi2")
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package consistent_hash
+
+import "time"
+
+/*
+	健康感知的节点选择。
+
+	被标记为down的节点仍然保留在环上，它的虚拟节点不会被摘除，也就不会
+	触发整个集群的哈希槽重新分配——这正是外部资料反复强调的一点：每一次
+	真正的Remove都会带来不小范围的key迁移，而瞬时故障通常只需要暂时跳过
+	这个节点，故障恢复后它应当拿回原来的那部分key。
+	MarkDown/MarkUp用于手动维护节点状态；GetLive/GetNLive在查找时跳过
+	当前down的节点。Config上还可以挂一个HealthChecker，让哈希环定期自己
+	探测成员是否存活并自动更新状态，状态变化会通过Events()广播出去，方便
+	调用方失效自己这边缓存的路由结果。
+*/
+
+// DefaultHealthCheckInterval 是未显式配置HealthCheckInterval时使用的探测周期
+const DefaultHealthCheckInterval = 5 * time.Second
+
+// HealthChecker 用于周期性探测某个成员是否存活
+type HealthChecker interface {
+	Check(member string) bool
+}
+
+// EventType 表示一次节点健康状态变化的类型
+type EventType int
+
+const (
+	EventMemberDown EventType = iota
+	EventMemberUp
+)
+
+// Event 表示一次节点健康状态变化，通过Events()订阅
+type Event struct {
+	Type   EventType
+	Member string
+}
+
+// eventBufferSize 是每个订阅者channel的缓冲大小，消费不及时时新事件会被丢弃
+const eventBufferSize = 16
+
+// MarkDown 将member标记为不可用。该节点仍保留在环上，GetLive/GetNLive会在
+// 查找时跳过它，但普通的Get不受影响。
+func (c *ConsistentHash) MarkDown(member string) {
+	c.Lock()
+	_, exists := c.members[member]
+	_, alreadyDown := c.down[member]
+	if exists && !alreadyDown {
+		c.down[member] = struct{}{}
+	}
+	c.Unlock()
+
+	if exists && !alreadyDown {
+		c.publish(Event{Type: EventMemberDown, Member: member})
+	}
+}
+
+// MarkUp 将member标记为恢复可用
+func (c *ConsistentHash) MarkUp(member string) {
+	c.Lock()
+	_, wasDown := c.down[member]
+	delete(c.down, member)
+	c.Unlock()
+
+	if wasDown {
+		c.publish(Event{Type: EventMemberUp, Member: member})
+	}
+}
+
+// IsDown 返回member当前是否被标记为不可用
+func (c *ConsistentHash) IsDown(member string) bool {
+	c.RLock()
+	defer c.RUnlock()
+	_, down := c.down[member]
+	return down
+}
+
+// GetLive 与Get类似，但会跳过被标记为down的节点；如果环上所有节点都
+// 不可用，退化为自然归属节点（即便它当前也是down的）。
+func (c *ConsistentHash) GetLive(key string) string {
+	c.RLock()
+	defer c.RUnlock()
+
+	if len(c.ring) == 0 {
+		return ""
+	}
+
+	idx, natural := c.findNode(c.hashKey(key))
+	if len(c.down) == 0 {
+		return natural
+	}
+
+	visited := make(map[string]struct{}, len(c.members))
+	for i := 0; i < len(c.ring); i++ {
+		member := c.hashMap[c.ring[idx]]
+		if _, ok := visited[member]; !ok {
+			visited[member] = struct{}{}
+			if _, down := c.down[member]; !down {
+				return member
+			}
+			if len(visited) == len(c.members) {
+				break
+			}
+		}
+		idx = (idx + 1) % len(c.ring)
+	}
+
+	return natural
+}
+
+// GetNLive 返回key对应的n个互不相同且当前存活的节点，用于副本选择；
+// 如果存活节点不足n个，返回所有能找到的存活节点。
+func (c *ConsistentHash) GetNLive(key string, n int) []string {
+	if n <= 0 {
+		return nil
+	}
+
+	c.RLock()
+	defer c.RUnlock()
+
+	if len(c.ring) == 0 {
+		return nil
+	}
+
+	idx, _ := c.findNode(c.hashKey(key))
+	visited := make(map[string]struct{}, len(c.members))
+	result := make([]string, 0, n)
+
+	for len(result) < n && len(visited) < len(c.members) {
+		member := c.hashMap[c.ring[idx]]
+		if _, ok := visited[member]; !ok {
+			visited[member] = struct{}{}
+			if _, down := c.down[member]; !down {
+				result = append(result, member)
+			}
+		}
+		idx = (idx + 1) % len(c.ring)
+	}
+
+	return result
+}
+
+// Events 返回一个只读channel，用于订阅节点健康状态变化。调用方应当持续
+// 消费该channel；缓冲区已满时，新发布的事件会被丢弃而不是阻塞哈希环。
+func (c *ConsistentHash) Events() <-chan Event {
+	ch := make(chan Event, eventBufferSize)
+	c.Lock()
+	c.subscribers = append(c.subscribers, ch)
+	c.Unlock()
+	return ch
+}
+
+// publish 将一次状态变化广播给所有订阅者
+func (c *ConsistentHash) publish(event Event) {
+	c.RLock()
+	defer c.RUnlock()
+	for _, ch := range c.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// 订阅者消费不及时，丢弃事件以避免阻塞健康检查流程
+		}
+	}
+}
+
+// startHealthCheck 启动后台goroutine，周期性调用config.HealthChecker探测
+// 所有成员的健康状态并自动MarkDown/MarkUp
+func (c *ConsistentHash) startHealthCheck() {
+	interval := c.config.HealthCheckInterval
+	if interval <= 0 {
+		interval = DefaultHealthCheckInterval
+	}
+	stop := make(chan struct{})
+	c.stopHealthCheck = stop
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.runHealthCheck()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// runHealthCheck 对当前所有成员执行一次健康探测
+func (c *ConsistentHash) runHealthCheck() {
+	checker := c.config.HealthChecker
+	if checker == nil {
+		return
+	}
+	for _, member := range c.Members() {
+		if checker.Check(member) {
+			c.MarkUp(member)
+		} else {
+			c.MarkDown(member)
+		}
+	}
+}
+
+// Close 停止后台健康检查goroutine（如果启用了HealthChecker）。对未启用
+// 健康检查的实例调用Close是安全的空操作。
+func (c *ConsistentHash) Close() {
+	c.Lock()
+	stopCh := c.stopHealthCheck
+	c.stopHealthCheck = nil
+	c.Unlock()
+
+	if stopCh != nil {
+		close(stopCh)
+	}
+}
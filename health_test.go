@@ -0,0 +1,65 @@
+package consistent_hash
+
+import "testing"
+
+func Test_GetLive_SkipsDownMembers(t *testing.T) {
+	ch := New(&Config{
+		Replicas: 10,
+		HashFunc: NewCRC32(),
+	})
+	ch.Add("node1", "node2", "node3")
+
+	key := "some-key"
+	natural := ch.Get(key)
+	ch.MarkDown(natural)
+
+	live := ch.GetLive(key)
+	if live == natural {
+		t.Fatalf("expected GetLive to skip the down member %s", natural)
+	}
+	if ch.IsDown(live) {
+		t.Fatalf("expected %s to not be marked down", live)
+	}
+
+	ch.MarkUp(natural)
+	if ch.IsDown(natural) {
+		t.Fatalf("expected %s to be marked up again", natural)
+	}
+}
+
+func Test_GetLive_AllDownFallsBackToNatural(t *testing.T) {
+	ch := New(&Config{
+		Replicas: 10,
+		HashFunc: NewCRC32(),
+	})
+	ch.Add("node1", "node2")
+
+	key := "some-key"
+	natural := ch.Get(key)
+	ch.MarkDown("node1")
+	ch.MarkDown("node2")
+
+	if got := ch.GetLive(key); got != natural {
+		t.Fatalf("expected fallback to natural member %s, got %s", natural, got)
+	}
+}
+
+func Test_Events_NotifiesOnMarkDown(t *testing.T) {
+	ch := New(&Config{
+		Replicas: 10,
+		HashFunc: NewCRC32(),
+	})
+	ch.Add("node1")
+	events := ch.Events()
+
+	ch.MarkDown("node1")
+
+	select {
+	case event := <-events:
+		if event.Type != EventMemberDown || event.Member != "node1" {
+			t.Fatalf("unexpected event: %+v", event)
+		}
+	default:
+		t.Fatal("expected a down event to be published")
+	}
+}
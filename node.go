@@ -0,0 +1,119 @@
+package consistent_hash
+
+import "sync"
+
+/*
+	Node 之上的类型化封装。
+
+	ConsistentHash本身只认识字符串标识，Get/GetN/Members等方法返回的也只是
+	这个标识。当调用方希望在每个成员上挂载更多的元数据（地址、机房、权重、
+	健康状态等任意payload）时，往往需要自己额外维护一张 map[string]*MyNode，
+	查找完节点名之后再去这张表里反查一次。
+
+	TypedHash把这张表收进了库内部：调用方的节点类型只需要实现Node接口，
+	Get/GetN/Members就可以直接返回调用方自己的类型，而不再是裸字符串。
+	底层仍然复用*ConsistentHash做哈希环的构建与查找，TypedHash只是在其上
+	附加了一层 key -> T 的映射。
+*/
+
+// Node 是可以加入一致性哈希环的成员需要实现的接口，Key()返回该成员在环上的
+// 唯一标识，一致性哈希只依赖这个标识做哈希计算和查找。
+type Node interface {
+	Key() string
+}
+
+// TypedHash 是*ConsistentHash在Node接口之上的类型化封装，Get/GetN/Members
+// 返回调用方的节点类型T，而不是裸的字符串标识。
+type TypedHash[T Node] struct {
+	ring  *ConsistentHash
+	mu    sync.RWMutex
+	nodes map[string]T
+}
+
+// NewTyped 创建一个新的类型化一致性哈希实例
+func NewTyped[T Node](config *Config) *TypedHash[T] {
+	return &TypedHash[T]{
+		ring:  New(config),
+		nodes: make(map[string]T),
+	}
+}
+
+// Add 添加一个或多个节点，权重为默认值DefaultWeight
+func (t *TypedHash[T]) Add(nodes ...T) {
+	for _, node := range nodes {
+		_ = t.AddWithWeight(node, DefaultWeight)
+	}
+}
+
+// AddWithWeight 添加一个带权重的节点
+func (t *TypedHash[T]) AddWithWeight(node T, weight int) error {
+	key := node.Key()
+	if err := t.ring.AddWithWeight(key, weight); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.nodes[key] = node
+	t.mu.Unlock()
+	return nil
+}
+
+// Remove 移除一个或多个节点
+func (t *TypedHash[T]) Remove(nodes ...T) {
+	keys := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		keys = append(keys, node.Key())
+	}
+	t.ring.Remove(keys...)
+
+	t.mu.Lock()
+	for _, key := range keys {
+		delete(t.nodes, key)
+	}
+	t.mu.Unlock()
+}
+
+// Get 返回key应当分配到的节点，ok为false表示哈希环为空
+func (t *TypedHash[T]) Get(key string) (node T, ok bool) {
+	member := t.ring.Get(key)
+	if member == "" {
+		return node, false
+	}
+	return t.lookup(member)
+}
+
+// GetN 返回key对应的n个互不相同的节点，用于选择副本
+func (t *TypedHash[T]) GetN(key string, n int) []T {
+	members := t.ring.GetN(key, n)
+	if len(members) == 0 {
+		return nil
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	result := make([]T, 0, len(members))
+	for _, member := range members {
+		if node, ok := t.nodes[member]; ok {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+// Members 返回环上所有的节点
+func (t *TypedHash[T]) Members() []T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	members := make([]T, 0, len(t.nodes))
+	for _, node := range t.nodes {
+		members = append(members, node)
+	}
+	return members
+}
+
+// lookup 根据字符串标识反查对应的节点
+func (t *TypedHash[T]) lookup(key string) (node T, ok bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	node, ok = t.nodes[key]
+	return node, ok
+}
@@ -0,0 +1,44 @@
+package consistent_hash
+
+import "testing"
+
+// serverNode是一个携带额外元数据的示例节点，用于验证TypedHash可以直接
+// 返回调用方的类型，而不需要调用方自己维护一张key到节点的反查表
+type serverNode struct {
+	id      string
+	address string
+}
+
+func (s serverNode) Key() string { return s.id }
+
+func Test_TypedHash(t *testing.T) {
+	th := NewTyped[serverNode](&Config{
+		Replicas: 10,
+		HashFunc: NewCRC32(),
+	})
+
+	nodes := []serverNode{
+		{id: "node1", address: "10.0.0.1:8080"},
+		{id: "node2", address: "10.0.0.2:8080"},
+		{id: "node3", address: "10.0.0.3:8080"},
+	}
+	th.Add(nodes...)
+
+	node, ok := th.Get("some-key")
+	if !ok {
+		t.Fatal("expected a node for non-empty ring")
+	}
+	if node.address == "" {
+		t.Fatalf("expected Get to return the node with its metadata, got %+v", node)
+	}
+
+	replicas := th.GetN("some-key", 2)
+	if len(replicas) != 2 {
+		t.Fatalf("expected 2 replicas, got %d", len(replicas))
+	}
+
+	th.Remove(nodes[0])
+	if len(th.Members()) != 2 {
+		t.Fatalf("expected 2 members after removal, got %d", len(th.Members()))
+	}
+}
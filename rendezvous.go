@@ -0,0 +1,189 @@
+package consistent_hash
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+/*
+	Rendezvous哈希（Highest Random Weight，HRW），作为Ring之外的另一种
+	节点选择策略。
+
+	对每个(node, key)组合计算一个分数：
+		score(node, key) = -ln(uniform01(hash(node||key))) / weight(node)
+	取分数最小的节点作为key的归属节点（GetN则取分数最小的n个）。
+
+	与Ring相比：
+	  - 不需要虚拟节点，省去了ring/hashMap占用的 Replicas×weight 份内存，
+	    也没有构建/排序哈希环的开销；
+	  - 天然具有最小扰动性：增删一个节点只会影响恰好属于该节点的那部分key，
+	    不依赖虚拟节点数量去逼近这个性质；
+	  - 代价是每次查找是O(N)（N为节点数），而Ring的查找是对有序环做二分，
+	    是O(log N)；节点数很大且查找QPS很高的场景，Ring通常更合适。
+*/
+
+// Strategy 用于在Config中选择底层实现
+type Strategy int
+
+const (
+	StrategyRing       Strategy = iota // 基于虚拟节点的一致性哈希环，默认策略
+	StrategyRendezvous                 // 基于HRW的Rendezvous哈希
+)
+
+// Hash 是Ring（*ConsistentHash）与Rendezvous共同实现的选择节点的接口，
+// 用于在二者之间切换而不影响调用方代码
+type Hash interface {
+	Add(members ...string)
+	AddWithWeight(member string, weight int) error
+	Remove(members ...string)
+	Get(key string) string
+	GetN(key string, n int) []string
+	Members() []string
+}
+
+// NewHash根据config.Strategy构建底层实现：StrategyRing返回*ConsistentHash，
+// StrategyRendezvous返回*Rendezvous，二者都满足Hash接口。
+func NewHash(config *Config) Hash {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	switch config.Strategy {
+	case StrategyRendezvous:
+		return NewRendezvous(config.HashFunc)
+	default:
+		return New(config)
+	}
+}
+
+// Rendezvous 实现基于HRW的节点选择，不依赖虚拟节点
+type Rendezvous struct {
+	hash    Hasher
+	weights map[string]int
+	members map[string]struct{}
+	sync.RWMutex
+}
+
+// NewRendezvous 创建一个新的Rendezvous哈希实例，hash为nil时使用DefaultHasher
+func NewRendezvous(hash Hasher) *Rendezvous {
+	if hash == nil {
+		hash = DefaultHasher
+	}
+	return &Rendezvous{
+		hash:    hash,
+		weights: make(map[string]int),
+		members: make(map[string]struct{}),
+	}
+}
+
+// addNode 添加一个带权重的节点
+func (r *Rendezvous) addNode(member string, weight int) error {
+	if weight <= 0 {
+		return fmt.Errorf("weight must be positive")
+	}
+	if _, ok := r.members[member]; ok || member == "" {
+		return fmt.Errorf("member already exists or empty")
+	}
+	r.members[member] = struct{}{}
+	r.weights[member] = weight
+	return nil
+}
+
+func (r *Rendezvous) Add(members ...string) {
+	r.Lock()
+	defer r.Unlock()
+	for _, member := range members {
+		_ = r.addNode(member, DefaultWeight)
+	}
+}
+
+// AddWithWeight 添加一个带权重的节点
+func (r *Rendezvous) AddWithWeight(member string, weight int) error {
+	r.Lock()
+	defer r.Unlock()
+	return r.addNode(member, weight)
+}
+
+func (r *Rendezvous) Remove(members ...string) {
+	r.Lock()
+	defer r.Unlock()
+	for _, member := range members {
+		delete(r.members, member)
+		delete(r.weights, member)
+	}
+}
+
+// score 计算标准的加权HRW分数：score(node, key) = -ln(uniform01(hash(node||key))) / weight(node)
+func (r *Rendezvous) score(member, key string) float64 {
+	h := r.hash.Hash([]byte(member + "\x00" + key))
+	// 把[0, 2^32-1]映射到左开右闭区间(0, 1]，避免对0取对数
+	u := (float64(h) + 1) / (float64(math.MaxUint32) + 1)
+	return -math.Log(u) / float64(r.weights[member])
+}
+
+// Get 返回key分数最小的节点
+func (r *Rendezvous) Get(key string) string {
+	r.RLock()
+	defer r.RUnlock()
+
+	if len(r.members) == 0 {
+		return ""
+	}
+
+	var best string
+	bestScore := math.Inf(1)
+	for member := range r.members {
+		if s := r.score(member, key); s < bestScore {
+			bestScore = s
+			best = member
+		}
+	}
+	return best
+}
+
+// GetN 返回key分数最小的n个互不相同的节点，节点数不足n时返回全部节点
+func (r *Rendezvous) GetN(key string, n int) []string {
+	if n <= 0 {
+		return nil
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+
+	if len(r.members) == 0 {
+		return nil
+	}
+
+	type scoredMember struct {
+		member string
+		score  float64
+	}
+	scored := make([]scoredMember, 0, len(r.members))
+	for member := range r.members {
+		scored = append(scored, scoredMember{member, r.score(member, key)})
+	}
+	sort.Slice(scored, func(i, j int) bool {
+		return scored[i].score < scored[j].score
+	})
+
+	if n > len(scored) {
+		n = len(scored)
+	}
+	result := make([]string, n)
+	for i := 0; i < n; i++ {
+		result[i] = scored[i].member
+	}
+	return result
+}
+
+// Members 返回当前所有节点
+func (r *Rendezvous) Members() []string {
+	r.RLock()
+	defer r.RUnlock()
+	members := make([]string, 0, len(r.members))
+	for member := range r.members {
+		members = append(members, member)
+	}
+	return members
+}
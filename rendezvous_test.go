@@ -0,0 +1,65 @@
+package consistent_hash
+
+import (
+	"fmt"
+	"testing"
+)
+
+func Test_Rendezvous_BasicAssignment(t *testing.T) {
+	r := NewRendezvous(NewCRC32())
+	r.Add("node1", "node2", "node3")
+
+	key := "some-key"
+	if got := r.Get(key); got == "" {
+		t.Fatal("expected a member")
+	}
+
+	replicas := r.GetN(key, 2)
+	if len(replicas) != 2 {
+		t.Fatalf("expected 2 replicas, got %d", len(replicas))
+	}
+	if replicas[0] == replicas[1] {
+		t.Fatalf("expected 2 distinct replicas, got %v", replicas)
+	}
+}
+
+// Test_Rendezvous_MinimalDisruption验证添加新节点后，大部分key仍然
+// 归属于原来的节点（HRW的最小扰动性），只有被新节点"抢走"的那部分会迁移
+func Test_Rendezvous_MinimalDisruption(t *testing.T) {
+	r := NewRendezvous(NewCRC32())
+	r.Add("node1", "node2", "node3")
+
+	const keyCount = 1000
+	before := make(map[string]string, keyCount)
+	for i := 0; i < keyCount; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		before[key] = r.Get(key)
+	}
+
+	r.Add("node4")
+
+	moved := 0
+	for key, oldMember := range before {
+		if r.Get(key) != oldMember {
+			moved++
+		}
+	}
+
+	// 4个等权重节点下，理论上约1/4的key会迁移到新节点；这里只断言
+	// 没有出现远超预期的大范围迁移
+	if moved > keyCount/2 {
+		t.Fatalf("too many keys moved after adding a node: %d/%d", moved, keyCount)
+	}
+}
+
+func Test_NewHash_SelectsStrategy(t *testing.T) {
+	ring := NewHash(&Config{Replicas: 10, HashFunc: NewCRC32(), Strategy: StrategyRing})
+	if _, ok := ring.(*ConsistentHash); !ok {
+		t.Fatalf("expected *ConsistentHash for StrategyRing, got %T", ring)
+	}
+
+	rendezvous := NewHash(&Config{HashFunc: NewCRC32(), Strategy: StrategyRendezvous})
+	if _, ok := rendezvous.(*Rendezvous); !ok {
+		t.Fatalf("expected *Rendezvous for StrategyRendezvous, got %T", rendezvous)
+	}
+}
@@ -0,0 +1,330 @@
+package consistent_hash
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+)
+
+/*
+	快照、序列化与恢复。
+
+	虚拟节点数量较多时（成百上千个节点 × Replicas × weight），进程启动时
+	重新计算每个虚拟节点的哈希值再排序的开销不可忽略。Snapshot提供了一份
+	环当前状态的不可变视图，可以通过Marshal写到磁盘/对象存储，下次启动时
+	用Unmarshal+Restore直接还原出一份*ConsistentHash，跳过重新哈希和排序。
+
+	Diff进一步回答"这些key会迁移到哪"的问题：给定变更前后的两份快照，
+	返回哪些哈希区间换了归属节点，调用方可以在真正执行一批Add/Remove之前，
+	先构造出变更后的快照做一次预览。
+*/
+
+// snapshotFormatVersion 是Marshal/Unmarshal使用的二进制格式版本号
+const snapshotFormatVersion = 1
+
+// Snapshot 是哈希环当前状态的不可变视图
+type Snapshot struct {
+	Replicas   int
+	HasherName string
+	Members    []string
+	Weights    map[string]int
+	Ring       []uint32          // 按升序排列
+	HashMap    map[uint32]string // 虚拟节点hash -> 物理节点
+}
+
+// namedHasher是可选接口，实现了该接口的Hasher在Snapshot中会记录自己的
+// 注册名，Restore时据此通过HasherByName重建同一个哈希函数
+type namedHasher interface {
+	Name() string
+}
+
+// Snapshot 返回当前哈希环状态的一份快照，可安全地在外部读取或序列化
+func (c *ConsistentHash) Snapshot() *Snapshot {
+	c.RLock()
+	defer c.RUnlock()
+
+	snap := &Snapshot{
+		Replicas: c.config.Replicas,
+		Members:  make([]string, 0, len(c.members)),
+		Weights:  make(map[string]int, len(c.weights)),
+		Ring:     make([]uint32, len(c.ring)),
+		HashMap:  make(map[uint32]string, len(c.hashMap)),
+	}
+	if named, ok := c.hash.(namedHasher); ok {
+		snap.HasherName = named.Name()
+	}
+	for member := range c.members {
+		snap.Members = append(snap.Members, member)
+	}
+	for member, weight := range c.weights {
+		snap.Weights[member] = weight
+	}
+	copy(snap.Ring, c.ring)
+	for hash, member := range c.hashMap {
+		snap.HashMap[hash] = member
+	}
+	return snap
+}
+
+// Restore根据快照重建一个*ConsistentHash，跳过重新计算虚拟节点哈希值
+// 与排序的过程；快照中记录的HasherName会通过HasherByName重新解析，
+// 解析失败（例如进程里没有注册该哈希函数）时返回error。
+func Restore(snap *Snapshot) (*ConsistentHash, error) {
+	if snap == nil {
+		return nil, fmt.Errorf("consistent_hash: snapshot is nil")
+	}
+
+	config := DefaultConfig()
+	config.Replicas = snap.Replicas
+	if snap.HasherName != "" {
+		hasher, err := HasherByName(snap.HasherName)
+		if err != nil {
+			return nil, err
+		}
+		config.HashFunc = hasher
+	}
+
+	c := New(config)
+	for _, member := range snap.Members {
+		c.members[member] = struct{}{}
+		c.weights[member] = snap.Weights[member]
+		c.loads[member] = 0
+	}
+	c.ring = make([]uint32, len(snap.Ring))
+	copy(c.ring, snap.Ring)
+	for hash, member := range snap.HashMap {
+		c.hashMap[hash] = member
+	}
+	return c, nil
+}
+
+// Marshal 是 c.Snapshot().Marshal(w) 的便捷写法
+func (c *ConsistentHash) Marshal(w io.Writer) error {
+	return c.Snapshot().Marshal(w)
+}
+
+// Marshal 将快照写成紧凑的二进制格式：hash值按环上的顺序做delta+varint编码，
+// 成员名做字符串驻留（intern），每个虚拟节点只记录成员的索引而不是重复写名字。
+func (s *Snapshot) Marshal(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	if err := writeUvarint(bw, snapshotFormatVersion); err != nil {
+		return err
+	}
+	if err := writeString(bw, s.HasherName); err != nil {
+		return err
+	}
+	if err := writeUvarint(bw, uint64(s.Replicas)); err != nil {
+		return err
+	}
+
+	memberIndex := make(map[string]int, len(s.Members))
+	for i, member := range s.Members {
+		memberIndex[member] = i
+	}
+
+	if err := writeUvarint(bw, uint64(len(s.Members))); err != nil {
+		return err
+	}
+	for _, member := range s.Members {
+		if err := writeString(bw, member); err != nil {
+			return err
+		}
+		if err := writeUvarint(bw, uint64(s.Weights[member])); err != nil {
+			return err
+		}
+	}
+
+	if err := writeUvarint(bw, uint64(len(s.Ring))); err != nil {
+		return err
+	}
+	var prev uint32
+	for _, hash := range s.Ring {
+		member := s.HashMap[hash]
+		idx, ok := memberIndex[member]
+		if !ok {
+			return fmt.Errorf("consistent_hash: ring entry references unknown member %q", member)
+		}
+		if err := writeUvarint(bw, uint64(hash-prev)); err != nil {
+			return err
+		}
+		if err := writeUvarint(bw, uint64(idx)); err != nil {
+			return err
+		}
+		prev = hash
+	}
+
+	return bw.Flush()
+}
+
+// Unmarshal 从Marshal写出的二进制格式中读出一份Snapshot
+func Unmarshal(r io.Reader) (*Snapshot, error) {
+	br := bufio.NewReader(r)
+
+	version, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if version != snapshotFormatVersion {
+		return nil, fmt.Errorf("consistent_hash: unsupported snapshot format version %d", version)
+	}
+
+	hasherName, err := readString(br)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+
+	memberCount, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]string, memberCount)
+	weights := make(map[string]int, memberCount)
+	for i := range members {
+		name, err := readString(br)
+		if err != nil {
+			return nil, err
+		}
+		weight, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		members[i] = name
+		weights[name] = int(weight)
+	}
+
+	ringLen, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+
+	ring := make([]uint32, ringLen)
+	hashMap := make(map[uint32]string, ringLen)
+	var prev uint32
+	for i := uint64(0); i < ringLen; i++ {
+		delta, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		idx, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		if idx >= uint64(len(members)) {
+			return nil, fmt.Errorf("consistent_hash: ring entry references out-of-range member index %d", idx)
+		}
+		hash := prev + uint32(delta)
+		ring[i] = hash
+		hashMap[hash] = members[idx]
+		prev = hash
+	}
+
+	return &Snapshot{
+		Replicas:   int(replicas),
+		HasherName: hasherName,
+		Members:    members,
+		Weights:    weights,
+		Ring:       ring,
+		HashMap:    hashMap,
+	}, nil
+}
+
+func writeUvarint(w io.Writer, v uint64) error {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+func writeString(w io.Writer, s string) error {
+	if err := writeUvarint(w, uint64(len(s))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+func readString(r *bufio.Reader) (string, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// KeyMigration 描述一段哈希区间(HashRangeStart, HashRangeEnd]在两份快照
+// 之间换了归属节点：From是旧快照中的归属节点，To是新快照中的归属节点
+type KeyMigration struct {
+	HashRangeStart uint32
+	HashRangeEnd   uint32
+	From           string
+	To             string
+}
+
+// Diff比较两份快照，返回归属节点发生变化的哈希区间列表，用于在真正执行
+// 一批Add/Remove之前预览会有哪些key发生迁移
+func Diff(oldSnap, newSnap *Snapshot) []KeyMigration {
+	if oldSnap == nil || newSnap == nil {
+		return nil
+	}
+
+	boundarySet := make(map[uint32]struct{}, len(oldSnap.Ring)+len(newSnap.Ring))
+	for _, hash := range oldSnap.Ring {
+		boundarySet[hash] = struct{}{}
+	}
+	for _, hash := range newSnap.Ring {
+		boundarySet[hash] = struct{}{}
+	}
+	if len(boundarySet) == 0 {
+		return nil
+	}
+
+	boundaries := make([]uint32, 0, len(boundarySet))
+	for hash := range boundarySet {
+		boundaries = append(boundaries, hash)
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i] < boundaries[j] })
+
+	migrations := make([]KeyMigration, 0)
+	for i, start := range boundaries {
+		end := boundaries[(i+1)%len(boundaries)]
+		// 区间(start, end]上的归属节点由"第一个>=end的环上entry"决定
+		// （即findNode/ownerAt的顺时针查找规则），所以要在end处采样，
+		// 而不是start——在start处采样得到的是上一个区间的归属节点。
+		from := ownerAt(oldSnap, end)
+		to := ownerAt(newSnap, end)
+		if from != to {
+			migrations = append(migrations, KeyMigration{
+				HashRangeStart: start,
+				HashRangeEnd:   end,
+				From:           from,
+				To:             to,
+			})
+		}
+	}
+	return migrations
+}
+
+// ownerAt 返回快照中负责hash这个位置的节点（顺时针找到的第一个节点）
+func ownerAt(snap *Snapshot, hash uint32) string {
+	if len(snap.Ring) == 0 {
+		return ""
+	}
+	idx := sort.Search(len(snap.Ring), func(i int) bool { return snap.Ring[i] >= hash })
+	if idx == len(snap.Ring) {
+		idx = 0
+	}
+	return snap.HashMap[snap.Ring[idx]]
+}
@@ -0,0 +1,117 @@
+package consistent_hash
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_Snapshot_RestoreRoundTrip(t *testing.T) {
+	ch := New(&Config{
+		Replicas: 20,
+		HashFunc: NewCRC32(),
+	})
+	ch.Add("node1", "node2", "node3")
+
+	snap := ch.Snapshot()
+	restored, err := Restore(snap)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	for i := 0; i < 50; i++ {
+		key := string(rune('a' + i%26))
+		if got, want := restored.Get(key), ch.Get(key); got != want {
+			t.Fatalf("restored ring disagrees with original for key %q: got %s, want %s", key, got, want)
+		}
+	}
+}
+
+func Test_Snapshot_MarshalUnmarshal(t *testing.T) {
+	ch := New(&Config{
+		Replicas: 20,
+		HashFunc: NewCRC32(),
+	})
+	ch.Add("node1", "node2", "node3")
+
+	var buf bytes.Buffer
+	if err := ch.Marshal(&buf); err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	snap, err := Unmarshal(&buf)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if snap.HasherName != "crc32" {
+		t.Fatalf("expected hasher name crc32, got %q", snap.HasherName)
+	}
+
+	restored, err := Restore(snap)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if got, want := restored.Get("some-key"), ch.Get("some-key"); got != want {
+		t.Fatalf("restored ring disagrees with original: got %s, want %s", got, want)
+	}
+}
+
+func Test_Diff_ReportsMigrationsOnAdd(t *testing.T) {
+	ch := New(&Config{
+		Replicas: 20,
+		HashFunc: NewCRC32(),
+	})
+	ch.Add("node1", "node2", "node3")
+	before := ch.Snapshot()
+
+	ch.Add("node4")
+	after := ch.Snapshot()
+
+	migrations := Diff(before, after)
+	if len(migrations) == 0 {
+		t.Fatal("expected at least one migration after adding a node")
+	}
+	for _, m := range migrations {
+		if m.To != "node4" {
+			t.Fatalf("expected new ranges to move to node4, got %+v", m)
+		}
+	}
+
+	// 采样一批哈希值，凡是归属节点真的发生变化的，必须恰好落在
+	// 某一个上报的(HashRangeStart, HashRangeEnd]里；没变化的，
+	// 不应该落在任何一个区间里。这样才算真正验证了区间边界，而不只是
+	// 验证"报告了一些迁移"。
+	const sampleCount = 4096
+	const maxHash uint32 = 4294967295
+	step := maxHash / sampleCount
+	for i := 0; i < sampleCount; i++ {
+		h := uint32(i) * step
+
+		oldOwner := ownerAt(before, h)
+		newOwner := ownerAt(after, h)
+		changed := oldOwner != newOwner
+
+		covered := false
+		for _, m := range migrations {
+			if inMigrationRange(m, h) {
+				covered = true
+				break
+			}
+		}
+
+		if changed && !covered {
+			t.Fatalf("hash %d moved %s->%s but is not covered by any reported range", h, oldOwner, newOwner)
+		}
+		if !changed && covered {
+			t.Fatalf("hash %d did not move (owner %s both before and after) but is covered by a reported range", h, oldOwner)
+		}
+	}
+}
+
+// inMigrationRange报告hash是否落在(m.HashRangeStart, m.HashRangeEnd]里，
+// 处理区间跨越0点的情况
+func inMigrationRange(m KeyMigration, hash uint32) bool {
+	if m.HashRangeStart < m.HashRangeEnd {
+		return hash > m.HashRangeStart && hash <= m.HashRangeEnd
+	}
+	return hash > m.HashRangeStart || hash <= m.HashRangeEnd
+}